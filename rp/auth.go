@@ -0,0 +1,182 @@
+package rp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Authenticator applies authentication to an outgoing request before it is sent
+type Authenticator interface {
+	Apply(req *http.Request) error
+}
+
+// RoundTripperMiddleware wraps an http.RoundTripper, letting callers plug
+// cross-cutting behavior (correlation IDs, proxy auth, logging, mTLS) into
+// every request a Client makes. Middleware is applied in order, with the
+// first entry outermost.
+type RoundTripperMiddleware func(next http.RoundTripper) http.RoundTripper
+
+// StaticTokenAuth authenticates with a fixed bearer token
+type StaticTokenAuth struct {
+	Token string
+}
+
+// NewStaticTokenAuth creates a StaticTokenAuth for token
+func NewStaticTokenAuth(token string) *StaticTokenAuth {
+	return &StaticTokenAuth{Token: token}
+}
+
+// Apply sets the Authorization header to the static bearer token
+func (a *StaticTokenAuth) Apply(req *http.Request) error {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.Token))
+	return nil
+}
+
+// OAuth2ClientCredentialsAuth authenticates using the OAuth2 client-credentials
+// grant, fetching an access token from TokenURL and caching it until it
+// expires or doRequest sees a 401 and calls refresh.
+type OAuth2ClientCredentialsAuth struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient is used to fetch tokens; defaults to http.DefaultClient
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewOAuth2ClientCredentialsAuth creates an OAuth2ClientCredentialsAuth for the given token endpoint and credentials
+func NewOAuth2ClientCredentialsAuth(tokenURL, clientID, clientSecret string, scopes ...string) *OAuth2ClientCredentialsAuth {
+	return &OAuth2ClientCredentialsAuth{TokenURL: tokenURL, ClientID: clientID, ClientSecret: clientSecret, Scopes: scopes}
+}
+
+// Apply sets the Authorization header to a cached or freshly fetched access token
+func (a *OAuth2ClientCredentialsAuth) Apply(req *http.Request) error {
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	return nil
+}
+
+// refresh discards the cached access token, forcing the next Apply to fetch a new one
+func (a *OAuth2ClientCredentialsAuth) refresh() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.accessToken = ""
+	a.expiresAt = time.Time{}
+}
+
+func (a *OAuth2ClientCredentialsAuth) token() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.accessToken != "" && time.Now().Before(a.expiresAt) {
+		return a.accessToken, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", a.ClientID)
+	form.Set("client_secret", a.ClientSecret)
+	if len(a.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.Scopes, " "))
+	}
+
+	httpClient := a.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.PostForm(a.TokenURL, form)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to fetch OAuth2 token from %s", a.TokenURL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("OAuth2 token request failed with status %s", resp.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", errors.Wrap(err, "failed to decode OAuth2 token response")
+	}
+
+	a.accessToken = body.AccessToken
+	if body.ExpiresIn > 0 {
+		a.expiresAt = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	} else {
+		a.expiresAt = time.Time{}
+	}
+	return a.accessToken, nil
+}
+
+// CachingAuth wraps another Authenticator and memoizes the Authorization
+// header it produces for TTL, so callers don't pay the cost of the inner
+// Authenticator (a signature, a lookup) on every request.
+type CachingAuth struct {
+	Inner Authenticator
+	TTL   time.Duration
+
+	mu        sync.Mutex
+	header    string
+	expiresAt time.Time
+}
+
+// NewCachingAuth wraps inner, caching its Authorization header for ttl
+func NewCachingAuth(inner Authenticator, ttl time.Duration) *CachingAuth {
+	return &CachingAuth{Inner: inner, TTL: ttl}
+}
+
+// Apply sets the Authorization header from cache, or from inner if the cache is empty or expired
+func (a *CachingAuth) Apply(req *http.Request) error {
+	a.mu.Lock()
+	if a.header != "" && time.Now().Before(a.expiresAt) {
+		header := a.header
+		a.mu.Unlock()
+		req.Header.Set("Authorization", header)
+		return nil
+	}
+	a.mu.Unlock()
+
+	probe := req.Clone(req.Context())
+	if err := a.Inner.Apply(probe); err != nil {
+		return err
+	}
+	header := probe.Header.Get("Authorization")
+
+	a.mu.Lock()
+	a.header = header
+	a.expiresAt = time.Now().Add(a.TTL)
+	a.mu.Unlock()
+
+	req.Header.Set("Authorization", header)
+	return nil
+}
+
+// refresh invalidates the cached header and, if Inner is itself refreshable, refreshes it too
+func (a *CachingAuth) refresh() {
+	a.mu.Lock()
+	a.header = ""
+	a.expiresAt = time.Time{}
+	a.mu.Unlock()
+
+	if r, ok := a.Inner.(refresher); ok {
+		r.refresh()
+	}
+}
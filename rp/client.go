@@ -1,11 +1,12 @@
 package rp
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -39,6 +40,26 @@ type Client struct {
 	Endpoint string
 	Token    string
 	Project  string
+
+	// HTTPClient is used for all requests; it is lazily defaulted to a
+	// client with a defaultHTTPTimeout if left nil. Set it (or its Timeout)
+	// to bound how long a stuck ReportPortal server can block a call.
+	HTTPClient *http.Client
+
+	// Auth authenticates every outgoing request. NewClient defaults it to a
+	// StaticTokenAuth wrapping Token; set it directly to plug in OAuth2,
+	// mTLS, or other custom authentication.
+	Auth Authenticator
+
+	// Middleware wraps the HTTP transport, applied in order with the first
+	// entry outermost, for cross-cutting concerns like correlation IDs or proxy auth.
+	Middleware []RoundTripperMiddleware
+
+	// httpClientOnce and authOnce guard the lazy defaulting of HTTPClient and
+	// Auth in httpClient/doRequest, since LogReporter's worker goroutines
+	// call doRequest concurrently.
+	httpClientOnce sync.Once
+	authOnce       sync.Once
 }
 
 // Activity defines users activity on the project
@@ -66,22 +87,6 @@ type Activity struct {
 	}
 }
 
-// Widget defines widget info
-type Widget struct {
-	Id       string `json:"widgetId"`
-	Size     []int  `json:"widgetSize"`
-	Position []int  `json:"widgetPosition"`
-}
-
-// Dashboard defines dashoard info
-type Dashboard []struct {
-	Owner   string    `json:"owner"`
-	Share   bool      `json:"share"`
-	Id      string    `json:"id"`
-	Name    string    `json:"name"`
-	Widgets []*Widget `json:"widgets"`
-}
-
 // NewClient creates new client for ReportPortal endpoint
 func NewClient(endpoint, project, token string, apiVersion int) *Client {
 	endpoint = strings.TrimSuffix(endpoint, "/")
@@ -105,52 +110,31 @@ func NewClient(endpoint, project, token string, apiVersion int) *Client {
 		Endpoint: esb.String(),
 		Project:  project,
 		Token:    token,
+		Auth:     NewStaticTokenAuth(token),
 	}
 }
 
 // CheckConnect checks connection to ReportPortal
 func (c *Client) CheckConnect() error {
+	return c.CheckConnectCtx(context.Background())
+}
+
+// CheckConnectCtx checks connection to ReportPortal, honoring ctx's deadline and cancellation
+func (c *Client) CheckConnectCtx(ctx context.Context) error {
 	url := fmt.Sprintf("%s/user", c.Endpoint)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return errors.Wrapf(err, "can't create a new request for %s", url)
 	}
 
-	resp, err := doRequest(req, c.Token)
-	defer resp.Body.Close()
-
+	resp, err := c.doRequest(ctx, req)
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute GET request %s", req.URL)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return errors.Errorf("failed with status %s", resp.Status)
-	}
-	return nil
-}
-
-// GetDashboard gets all dashboard resources for project
-func (c *Client) GetDashboard() (*Dashboard, error) {
-	url := fmt.Sprintf("%s/%s/dashboard", c.Endpoint, c.Project)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, errors.Wrapf(err, "can't create request for %s", url)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := doRequest(req, c.Token)
 	defer resp.Body.Close()
 
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to execute GET request for %s", url)
-	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Errorf("failed with status %s", resp.Status)
-	}
-
-	var d *Dashboard
-	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
-		return nil, errors.Wrap(err, "failed to decode response for dashboard")
+		return errors.Errorf("failed with status %s", resp.Status)
 	}
-	return d, nil
+	return nil
 }
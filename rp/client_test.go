@@ -0,0 +1,33 @@
+package rp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestDoRequestConcurrentLazyInit calls doRequest from many goroutines on a
+// Client whose HTTPClient/Auth lazy defaulting hasn't run yet, so a data race
+// on httpClientOnce/authOnce would be caught under go test -race.
+func TestDoRequestConcurrentLazyInit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "project", "token", 1)
+	client.HTTPClient = nil
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.CheckConnect(); err != nil {
+				t.Errorf("CheckConnect failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
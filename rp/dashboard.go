@@ -0,0 +1,185 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Widget defines widget info
+type Widget struct {
+	Id       string `json:"widgetId"`
+	Size     []int  `json:"widgetSize"`
+	Position []int  `json:"widgetPosition"`
+}
+
+// DashboardInfo defines a single dashboard as returned by ReportPortal
+type DashboardInfo struct {
+	Owner   string    `json:"owner"`
+	Share   bool      `json:"share"`
+	Id      string    `json:"id"`
+	Name    string    `json:"name"`
+	Widgets []*Widget `json:"widgets"`
+}
+
+// Dashboard defines dashoard info
+type Dashboard []*DashboardInfo
+
+// CreateDashboardRequest defines the body for CreateDashboard
+type CreateDashboardRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Share       bool   `json:"share,omitempty"`
+}
+
+// CreateDashboardResponse defines the response body for CreateDashboard
+type CreateDashboardResponse struct {
+	Id string `json:"id"`
+}
+
+// UpdateDashboardRequest defines the body for UpdateDashboard
+type UpdateDashboardRequest struct {
+	Name  string `json:"name,omitempty"`
+	Share bool   `json:"share,omitempty"`
+}
+
+// AddWidgetRequest defines the body for AddWidget
+type AddWidgetRequest struct {
+	AddWidget *Widget `json:"addWidget"`
+}
+
+// RemoveWidgetRequest defines the body for RemoveWidget
+type RemoveWidgetRequest struct {
+	RemoveWidget string `json:"removeWidget"`
+}
+
+// UpdateWidgetPositionRequest defines the body for UpdateWidgetPosition
+type UpdateWidgetPositionRequest struct {
+	UpdateWidgets []*Widget `json:"updateWidgets"`
+}
+
+// WidgetSeriesPoint defines a single (x, y) point in a WidgetSeries
+type WidgetSeriesPoint struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+// WidgetSeries defines one named series of a chart widget's content
+type WidgetSeries struct {
+	Id     string               `json:"id"`
+	Points []*WidgetSeriesPoint `json:"points"`
+}
+
+// WidgetContent defines the content payload for a widget, as returned by
+// GetWidget. Series is populated for chart widgets, Result for table
+// widgets; which is set depends on the widget's Type.
+type WidgetContent struct {
+	Id     string                   `json:"widgetId"`
+	Name   string                   `json:"name"`
+	Type   string                   `json:"widgetType"`
+	Series []*WidgetSeries          `json:"series,omitempty"`
+	Result []map[string]interface{} `json:"result,omitempty"`
+}
+
+// CreateDashboard creates a new dashboard for the project
+func (c *Client) CreateDashboard(req *CreateDashboardRequest) (*CreateDashboardResponse, error) {
+	return c.CreateDashboardCtx(context.Background(), req)
+}
+
+// CreateDashboardCtx creates a new dashboard for the project, honoring ctx's deadline and cancellation
+func (c *Client) CreateDashboardCtx(ctx context.Context, req *CreateDashboardRequest) (*CreateDashboardResponse, error) {
+	url := fmt.Sprintf("%s/%s/dashboard", c.Endpoint, c.Project)
+
+	var resp CreateDashboardResponse
+	if err := c.doJSON(ctx, http.MethodPost, url, req, http.StatusCreated, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDashboard gets all dashboard resources for project
+func (c *Client) GetDashboard() (*Dashboard, error) {
+	return c.GetDashboardCtx(context.Background())
+}
+
+// GetDashboardCtx gets all dashboard resources for project, honoring ctx's deadline and cancellation
+func (c *Client) GetDashboardCtx(ctx context.Context) (*Dashboard, error) {
+	url := fmt.Sprintf("%s/%s/dashboard", c.Endpoint, c.Project)
+
+	var d Dashboard
+	if err := c.doJSON(ctx, http.MethodGet, url, nil, http.StatusOK, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// UpdateDashboard updates the name and sharing of the dashboard identified by id
+func (c *Client) UpdateDashboard(id string, req *UpdateDashboardRequest) error {
+	return c.UpdateDashboardCtx(context.Background(), id, req)
+}
+
+// UpdateDashboardCtx updates the dashboard identified by id, honoring ctx's deadline and cancellation
+func (c *Client) UpdateDashboardCtx(ctx context.Context, id string, req *UpdateDashboardRequest) error {
+	url := fmt.Sprintf("%s/%s/dashboard/%s", c.Endpoint, c.Project, id)
+	return c.doJSON(ctx, http.MethodPut, url, req, http.StatusOK, nil)
+}
+
+// DeleteDashboard deletes the dashboard identified by id
+func (c *Client) DeleteDashboard(id string) error {
+	return c.DeleteDashboardCtx(context.Background(), id)
+}
+
+// DeleteDashboardCtx deletes the dashboard identified by id, honoring ctx's deadline and cancellation
+func (c *Client) DeleteDashboardCtx(ctx context.Context, id string) error {
+	url := fmt.Sprintf("%s/%s/dashboard/%s", c.Endpoint, c.Project, id)
+	return c.doJSON(ctx, http.MethodDelete, url, nil, http.StatusOK, nil)
+}
+
+// AddWidget adds widget to the dashboard identified by dashboardId
+func (c *Client) AddWidget(dashboardId string, widget *Widget) error {
+	return c.AddWidgetCtx(context.Background(), dashboardId, widget)
+}
+
+// AddWidgetCtx adds widget to the dashboard identified by dashboardId, honoring ctx's deadline and cancellation
+func (c *Client) AddWidgetCtx(ctx context.Context, dashboardId string, widget *Widget) error {
+	url := fmt.Sprintf("%s/%s/dashboard/%s/add", c.Endpoint, c.Project, dashboardId)
+	return c.doJSON(ctx, http.MethodPut, url, &AddWidgetRequest{AddWidget: widget}, http.StatusOK, nil)
+}
+
+// RemoveWidget removes the widget identified by widgetId from the dashboard identified by dashboardId
+func (c *Client) RemoveWidget(dashboardId, widgetId string) error {
+	return c.RemoveWidgetCtx(context.Background(), dashboardId, widgetId)
+}
+
+// RemoveWidgetCtx removes widgetId from dashboardId, honoring ctx's deadline and cancellation
+func (c *Client) RemoveWidgetCtx(ctx context.Context, dashboardId, widgetId string) error {
+	url := fmt.Sprintf("%s/%s/dashboard/%s/remove", c.Endpoint, c.Project, dashboardId)
+	return c.doJSON(ctx, http.MethodPut, url, &RemoveWidgetRequest{RemoveWidget: widgetId}, http.StatusOK, nil)
+}
+
+// UpdateWidgetPosition updates the size and position of widgets on the dashboard identified by dashboardId
+func (c *Client) UpdateWidgetPosition(dashboardId string, widgets []*Widget) error {
+	return c.UpdateWidgetPositionCtx(context.Background(), dashboardId, widgets)
+}
+
+// UpdateWidgetPositionCtx updates widget positions on dashboardId, honoring ctx's deadline and cancellation
+func (c *Client) UpdateWidgetPositionCtx(ctx context.Context, dashboardId string, widgets []*Widget) error {
+	url := fmt.Sprintf("%s/%s/dashboard/%s", c.Endpoint, c.Project, dashboardId)
+	return c.doJSON(ctx, http.MethodPut, url, &UpdateWidgetPositionRequest{UpdateWidgets: widgets}, http.StatusOK, nil)
+}
+
+// GetWidget gets the content of the widget identified by id, such as chart series or table rows
+func (c *Client) GetWidget(id string, page *Page) (*WidgetContent, error) {
+	return c.GetWidgetCtx(context.Background(), id, page)
+}
+
+// GetWidgetCtx gets the content of the widget identified by id, honoring ctx's deadline and cancellation
+func (c *Client) GetWidgetCtx(ctx context.Context, id string, page *Page) (*WidgetContent, error) {
+	url := fmt.Sprintf("%s/%s/widget/%s", c.Endpoint, c.Project, id)
+
+	var content WidgetContent
+	if err := c.doJSONPaged(ctx, http.MethodGet, url, nil, page, http.StatusOK, &content); err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
@@ -0,0 +1,106 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const (
+	FilterTypeLaunch   = "launch"
+	FilterTypeTestItem = "TestItem"
+
+	FilterConditionEquals      = "eq"
+	FilterConditionNotEquals   = "ne"
+	FilterConditionContains    = "cnt"
+	FilterConditionGreaterThan = "gt"
+	FilterConditionLessThan    = "lt"
+	FilterConditionIn          = "in"
+	FilterConditionHas         = "has"
+)
+
+// FilterCondition defines a single filtering rule on FilteringField, e.g.
+// "name cnt smoke"
+type FilterCondition struct {
+	FilteringField string `json:"filteringField"`
+	Condition      string `json:"condition"`
+	Value          string `json:"value"`
+	Negative       bool   `json:"negative,omitempty"`
+}
+
+// FilterOrder defines a sort applied by a UserFilter
+type FilterOrder struct {
+	SortingColumn string `json:"sortingColumn"`
+	IsAsc         bool   `json:"isAsc"`
+}
+
+// UserFilter defines a saved search filter over launches or test items,
+// reusable across widgets and the ReportPortal UI
+type UserFilter struct {
+	Id          string             `json:"id,omitempty"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type"`
+	Share       bool               `json:"share,omitempty"`
+	Conditions  []*FilterCondition `json:"conditions"`
+	Orders      []*FilterOrder     `json:"orders,omitempty"`
+}
+
+// CreateFilterResponse defines the response body for CreateFilter
+type CreateFilterResponse struct {
+	Id string `json:"id"`
+}
+
+// FilterList defines a paginated list of UserFilters as returned by ListFilters
+type FilterList struct {
+	Content []*UserFilter `json:"content"`
+	Page    struct {
+		Number        int
+		Size          int
+		TotalElements int
+		TotalPages    int
+	} `json:"page"`
+}
+
+// CreateFilter creates a new saved filter for the project
+func (c *Client) CreateFilter(filter *UserFilter) (*CreateFilterResponse, error) {
+	return c.CreateFilterCtx(context.Background(), filter)
+}
+
+// CreateFilterCtx creates a new saved filter for the project, honoring ctx's deadline and cancellation
+func (c *Client) CreateFilterCtx(ctx context.Context, filter *UserFilter) (*CreateFilterResponse, error) {
+	url := fmt.Sprintf("%s/%s/filter", c.Endpoint, c.Project)
+
+	var resp CreateFilterResponse
+	if err := c.doJSON(ctx, http.MethodPost, url, filter, http.StatusCreated, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListFilters lists the saved filters for the project
+func (c *Client) ListFilters(page *Page) (*FilterList, error) {
+	return c.ListFiltersCtx(context.Background(), page)
+}
+
+// ListFiltersCtx lists the saved filters for the project, honoring ctx's deadline and cancellation
+func (c *Client) ListFiltersCtx(ctx context.Context, page *Page) (*FilterList, error) {
+	url := fmt.Sprintf("%s/%s/filter", c.Endpoint, c.Project)
+
+	var list FilterList
+	if err := c.doJSONPaged(ctx, http.MethodGet, url, nil, page, http.StatusOK, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// DeleteFilter deletes the saved filter identified by id
+func (c *Client) DeleteFilter(id string) error {
+	return c.DeleteFilterCtx(context.Background(), id)
+}
+
+// DeleteFilterCtx deletes the saved filter identified by id, honoring ctx's deadline and cancellation
+func (c *Client) DeleteFilterCtx(ctx context.Context, id string) error {
+	url := fmt.Sprintf("%s/%s/filter/%s", c.Endpoint, c.Project, id)
+	return c.doJSON(ctx, http.MethodDelete, url, nil, http.StatusOK, nil)
+}
@@ -0,0 +1,328 @@
+package rp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Launch defines a launch (a single test run) structure
+type Launch struct {
+	Id          string
+	Name        string
+	Description string
+	Mode        string
+	Tags        []string
+	StartTime   time.Time
+
+	// Rerun marks this launch as a rerun of a previous one, collapsing it
+	// into RerunOf's history in the ReportPortal UI instead of starting a new
+	// launch.
+	Rerun bool
+	// RerunOf is the UUID of the launch being rerun. Required when Rerun is set.
+	RerunOf string
+
+	client *Client
+
+	// itemStackMu guards itemStack, since StartNestedStep/FinishCtx may be
+	// called concurrently across goroutines reporting different branches of
+	// the same launch (e.g. parallel Ginkgo/godog specs).
+	itemStackMu sync.Mutex
+	// itemStack tracks the currently open items started via
+	// TestItem.StartNestedStep, innermost last, so BDD-style frameworks
+	// reporting arbitrarily nested Given/When/Then steps don't have to track
+	// parents themselves. finishDescendants only ever finishes entries it can
+	// prove (via TestItem.Parent) are descendants of the item being finished,
+	// so an item from an unrelated branch that happens to share the stack is
+	// never force-finished.
+	itemStack []*TestItem
+}
+
+// NewLaunch creates a new launch
+func NewLaunch(client *Client, name, description string, tags []string) *Launch {
+	return &Launch{
+		Name:        name,
+		Description: description,
+		Mode:        ModeDefault,
+		Tags:        tags,
+		client:      client,
+	}
+}
+
+// Start starts the launch
+func (l *Launch) Start() error {
+	return l.StartCtx(context.Background())
+}
+
+// StartCtx starts the launch, honoring ctx's deadline and cancellation
+func (l *Launch) StartCtx(ctx context.Context) error {
+	url := fmt.Sprintf("%s/%s/launch", l.client.Endpoint, l.client.Project)
+	data := struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		StartTime   int64    `json:"start_time"`
+		Tags        []string `json:"tags"`
+		Mode        string   `json:"mode"`
+		Rerun       bool     `json:"rerun,omitempty"`
+		RerunOf     string   `json:"rerun_of,omitempty"`
+	}{
+		Name:        l.Name,
+		Description: l.Description,
+		StartTime:   toTimestamp(time.Now()),
+		Tags:        l.Tags,
+		Mode:        l.Mode,
+		Rerun:       l.Rerun,
+		RerunOf:     l.RerunOf,
+	}
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal object %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.client.doRequest(ctx, req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	v := struct {
+		Id string `json:"id"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return errors.Wrapf(err, "failed to decode response from %s", req.URL)
+	}
+	l.Id = v.Id
+	return nil
+}
+
+// Finish finishes the launch with status
+func (l *Launch) Finish(status string) error {
+	return l.FinishCtx(context.Background(), status)
+}
+
+// FinishCtx finishes the launch with status, honoring ctx's deadline and cancellation
+func (l *Launch) FinishCtx(ctx context.Context, status string) error {
+	return l.endCtx(ctx, ActionFinish, status)
+}
+
+// Stop forcibly stops a still-running launch with status, e.g. after a crash
+// left it unfinished
+func (l *Launch) Stop(status string) error {
+	return l.StopCtx(context.Background(), status)
+}
+
+// StopCtx forcibly stops the launch with status, honoring ctx's deadline and cancellation
+func (l *Launch) StopCtx(ctx context.Context, status string) error {
+	return l.endCtx(ctx, ActionStop, status)
+}
+
+// endCtx posts the end_time/status body to the launch's finish or stop
+// endpoint, depending on action
+func (l *Launch) endCtx(ctx context.Context, action, status string) error {
+	url := fmt.Sprintf("%s/%s/launch/%s/%s", l.client.Endpoint, l.client.Project, l.Id, action)
+	data := struct {
+		EndTime int64  `json:"end_time"`
+		Status  string `json:"status"`
+	}{toTimestamp(time.Now()), status}
+
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal request data %v", data)
+	}
+
+	r := bytes.NewReader(b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PUT request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.client.doRequest(ctx, req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute PUT request to %s", req.URL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// FindItemByUniqueID looks up an item previously reported under l by
+// uniqueID (typically a hash of the item's name and parent path), so a test
+// framework that doesn't retain item UUIDs across process restarts can still
+// link a rerun to the attempt it replaces
+func (l *Launch) FindItemByUniqueID(uniqueID string) (*TestItem, error) {
+	return l.FindItemByUniqueIDCtx(context.Background(), uniqueID)
+}
+
+// FindItemByUniqueIDCtx looks up an item by uniqueID, honoring ctx's deadline and cancellation
+func (l *Launch) FindItemByUniqueIDCtx(ctx context.Context, uniqueID string) (*TestItem, error) {
+	reqURL := fmt.Sprintf("%s/%s/item?filter.eq.uniqueId=%s&filter.eq.launchId=%s",
+		l.client.Endpoint, l.client.Project, url.QueryEscape(uniqueID), url.QueryEscape(l.Id))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create request for %s", reqURL)
+	}
+
+	resp, err := l.client.doRequest(ctx, req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute GET request %s", req.URL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	var page struct {
+		Content []struct {
+			Id   string `json:"id"`
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode response from %s", req.URL)
+	}
+	if len(page.Content) == 0 {
+		return nil, errors.Errorf("no item found with unique id %s", uniqueID)
+	}
+
+	item := page.Content[0]
+	return &TestItem{
+		Id:     item.Id,
+		Name:   item.Name,
+		Type:   item.Type,
+		client: l.client,
+		launch: l,
+	}, nil
+}
+
+// pushItem pushes item onto l's open-item stack
+func (l *Launch) pushItem(item *TestItem) {
+	l.itemStackMu.Lock()
+	l.itemStack = append(l.itemStack, item)
+	l.itemStackMu.Unlock()
+}
+
+// CurrentItem returns the innermost item currently open via
+// TestItem.StartNestedStep, or nil if none are open
+func (l *Launch) CurrentItem() *TestItem {
+	l.itemStackMu.Lock()
+	defer l.itemStackMu.Unlock()
+	if len(l.itemStack) == 0 {
+		return nil
+	}
+	return l.itemStack[len(l.itemStack)-1]
+}
+
+// PopItem removes and returns the innermost open item, or nil if none are open
+func (l *Launch) PopItem() *TestItem {
+	l.itemStackMu.Lock()
+	defer l.itemStackMu.Unlock()
+	if len(l.itemStack) == 0 {
+		return nil
+	}
+	item := l.itemStack[len(l.itemStack)-1]
+	l.itemStack = l.itemStack[:len(l.itemStack)-1]
+	return item
+}
+
+// removeItem removes item from l's open-item stack wherever it sits, rather
+// than assuming it's on top. Items aren't always finished in push order: two
+// concurrent goroutines reporting independent branches interleave their
+// pushes, so the item being finished may be buried under one pushed by the
+// other branch.
+func (l *Launch) removeItem(item *TestItem) bool {
+	l.itemStackMu.Lock()
+	defer l.itemStackMu.Unlock()
+	for i, it := range l.itemStack {
+		if it == item {
+			l.itemStack = append(l.itemStack[:i], l.itemStack[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// openDescendants returns the items currently open on l's stack that are
+// descendants of until, deepest first, so finishDescendants closes
+// grandchildren before their parents.
+func (l *Launch) openDescendants(until *TestItem) []*TestItem {
+	l.itemStackMu.Lock()
+	var descendants []*TestItem
+	for _, it := range l.itemStack {
+		if isDescendant(it, until) {
+			descendants = append(descendants, it)
+		}
+	}
+	l.itemStackMu.Unlock()
+
+	sort.Slice(descendants, func(i, j int) bool {
+		return itemDepth(descendants[i]) > itemDepth(descendants[j])
+	})
+	return descendants
+}
+
+// finishDescendants finishes, innermost first, any open items on l's stack
+// that are descendants of until, so until's nested steps are closed before
+// until itself finishes, matching ReportPortal's server-side expectations.
+// An unrelated item left on the stack by a concurrent goroutine reporting a
+// different branch is never force-finished, regardless of where it sits
+// relative to until's descendants.
+func (l *Launch) finishDescendants(ctx context.Context, until *TestItem, status string) error {
+	for _, item := range l.openDescendants(until) {
+		if !l.removeItem(item) {
+			continue
+		}
+		if err := item.FinishCtx(ctx, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemDepth counts item's ancestors, so openDescendants can order deeper
+// items ahead of shallower ones.
+func itemDepth(item *TestItem) int {
+	depth := 0
+	for p := item.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return depth
+}
+
+// isDescendant reports whether item is a (possibly indirect) child of ancestor
+func isDescendant(item, ancestor *TestItem) bool {
+	for p := item.Parent; p != nil; p = p.Parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package rp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestFinishDescendantsSkipsUnrelatedSibling finishes a parent item whose
+// nested child sits on top of the shared item stack, above an unrelated
+// sibling pushed earlier by a different branch, and confirms
+// finishDescendants finishes the descendant but skips the sibling instead
+// of force-finishing it.
+func TestFinishDescendantsSkipsUnrelatedSibling(t *testing.T) {
+	var finishedIDs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimSuffix(r.URL.Path, "/"), "/")
+		finishedIDs = append(finishedIDs, parts[len(parts)-1])
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "project", "token", 1)
+	launch := &Launch{Id: "launch-1", client: client}
+
+	sibling := &TestItem{Id: "sibling", client: client, launch: launch}
+	parent := &TestItem{Id: "parent", client: client, launch: launch}
+	child := &TestItem{Id: "child", client: client, launch: launch, Parent: parent}
+
+	launch.pushItem(sibling)
+	launch.pushItem(child)
+
+	if err := parent.FinishCtx(context.Background(), StatusPassed); err != nil {
+		t.Fatalf("FinishCtx failed: %v", err)
+	}
+
+	want := []string{"child", "parent"}
+	if len(finishedIDs) != len(want) || finishedIDs[0] != want[0] || finishedIDs[1] != want[1] {
+		t.Fatalf("expected finish requests %v, got %v", want, finishedIDs)
+	}
+
+	if got := launch.CurrentItem(); got != sibling {
+		t.Fatalf("expected unrelated sibling to remain on stack untouched, got %v", got)
+	}
+}
+
+// TestFinishItemOutOfLIFOOrder finishes an item that isn't on top of the
+// shared item stack, simulating two concurrent branches interleaving their
+// pushes, and confirms the finished item is removed from wherever it sits
+// instead of being left buried under the item pushed after it.
+func TestFinishItemOutOfLIFOOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "project", "token", 1)
+	launch := &Launch{Id: "launch-1", client: client}
+
+	b1 := &TestItem{Id: "b1", client: client, launch: launch}
+	b2 := &TestItem{Id: "b2", client: client, launch: launch}
+
+	launch.pushItem(b1)
+	launch.pushItem(b2)
+
+	if err := b1.FinishCtx(context.Background(), StatusPassed); err != nil {
+		t.Fatalf("FinishCtx failed: %v", err)
+	}
+
+	if launch.removeItem(b1) {
+		t.Fatal("expected b1 to already be removed from the stack after finishing")
+	}
+	if got := launch.CurrentItem(); got != b2 {
+		t.Fatalf("expected b2 to remain the only open item, got %v", got)
+	}
+}
@@ -0,0 +1,367 @@
+package rp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// logEntry defines a single queued log line for the reporter
+type logEntry struct {
+	item       *TestItem
+	message    string
+	level      string
+	attachment *Attachment
+}
+
+// LogReporterConfig configures a LogReporter
+type LogReporterConfig struct {
+	// Workers is the number of goroutines flushing batches concurrently
+	Workers int
+	// BatchSize is the maximum number of entries sent in a single request
+	BatchSize int
+	// BatchTimeout is the maximum time an incomplete batch waits before being flushed
+	BatchTimeout time.Duration
+	// QueueSize is the capacity of the buffered queue
+	QueueSize int
+	// DropOldest makes Push drop the oldest queued entry instead of blocking when the queue is full
+	DropOldest bool
+	// MaxRetries is the number of retry attempts for a batch on 5xx/network errors
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential backoff delay
+	MaxBackoff time.Duration
+}
+
+// defaultLogReporterConfig fills unset fields with sane defaults
+func defaultLogReporterConfig(cfg LogReporterConfig) LogReporterConfig {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.BatchTimeout <= 0 {
+		cfg.BatchTimeout = time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 200 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Second
+	}
+	return cfg
+}
+
+// LogReporter asynchronously batches TestItem.Log calls into multipart
+// requests so callers don't pay one HTTP round-trip per log line
+type LogReporter struct {
+	client *Client
+	config LogReporterConfig
+
+	queue   chan *logEntry
+	pending int64
+
+	// closeMu serializes Push against Close: Push holds it for read while
+	// sending to queue, Close takes it for write before closing queue, so a
+	// Push in flight when Close is called either finishes its send first or
+	// observes closed and skips it, and no send ever races a closed channel.
+	closeMu   sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewLogReporter creates a LogReporter that flushes log entries for client
+func NewLogReporter(client *Client, cfg LogReporterConfig) *LogReporter {
+	cfg = defaultLogReporterConfig(cfg)
+	r := &LogReporter{
+		client: client,
+		config: cfg,
+		queue:  make(chan *logEntry, cfg.QueueSize),
+	}
+	r.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go r.worker()
+	}
+	return r
+}
+
+// Push queues a log entry for item to be flushed asynchronously. Depending on
+// config.DropOldest, Push either blocks until the queue has room or drops the
+// oldest queued entry to make room for the new one. Push is a no-op once
+// Close has been called, so a producer still emitting log lines while the
+// harness is tearing down is silently dropped instead of panicking.
+func (r *LogReporter) Push(item *TestItem, message, level string, attachment *Attachment) {
+	r.closeMu.RLock()
+	defer r.closeMu.RUnlock()
+	if r.closed {
+		return
+	}
+
+	entry := &logEntry{item: item, message: message, level: level, attachment: attachment}
+	atomic.AddInt64(&r.pending, 1)
+
+	if !r.config.DropOldest {
+		r.queue <- entry
+		return
+	}
+
+	select {
+	case r.queue <- entry:
+	default:
+		select {
+		case <-r.queue:
+			atomic.AddInt64(&r.pending, -1)
+		default:
+		}
+		select {
+		case r.queue <- entry:
+		default:
+			// queue refilled by a concurrent Push between the drop and the retry; drop this entry
+			atomic.AddInt64(&r.pending, -1)
+		}
+	}
+}
+
+// Flush blocks until all queued entries have been handled, or ctx is done. An
+// entry counts as handled once its batch was sent, or dropped after its
+// retries ran out or its attachment could not be read.
+func (r *LogReporter) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&r.pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Close stops accepting new entries and waits for in-flight workers to drain the queue
+func (r *LogReporter) Close() error {
+	r.closeOnce.Do(func() {
+		r.closeMu.Lock()
+		r.closed = true
+		close(r.queue)
+		r.closeMu.Unlock()
+	})
+	r.wg.Wait()
+	return nil
+}
+
+func (r *LogReporter) worker() {
+	defer r.wg.Done()
+
+	batch := make([]*logEntry, 0, r.config.BatchSize)
+	timer := time.NewTimer(r.config.BatchTimeout)
+	defer timer.Stop()
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		r.sendWithRetry(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-r.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= r.config.BatchSize {
+				flush()
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(r.config.BatchTimeout)
+			}
+		case <-timer.C:
+			flush()
+			timer.Reset(r.config.BatchTimeout)
+		}
+	}
+}
+
+// sendWithRetry sends batch, retrying on 5xx/network errors with exponential
+// backoff. Attachment data is snapshotted into memory once up front, since a
+// retry re-reads each attachment from scratch and the underlying io.Reader
+// (an os.File already advanced, a pipe, a network body) may not be
+// re-readable, or may simply be empty the second time around.
+func (r *LogReporter) sendWithRetry(batch []*logEntry) {
+	defer atomic.AddInt64(&r.pending, -int64(len(batch)))
+
+	snapshots, err := snapshotAttachments(batch)
+	if err != nil {
+		return
+	}
+
+	backoff := r.config.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		resetAttachments(batch, snapshots)
+		retriable, err := r.send(batch)
+		if err == nil || !retriable || attempt >= r.config.MaxRetries {
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > r.config.MaxBackoff {
+			backoff = r.config.MaxBackoff
+		}
+	}
+}
+
+// snapshotAttachments reads each entry's attachment fully into memory once,
+// so resetAttachments can rebuild a fresh reader over the same bytes for
+// every retry attempt.
+func snapshotAttachments(batch []*logEntry) ([][]byte, error) {
+	snapshots := make([][]byte, len(batch))
+	for i, e := range batch {
+		if e.attachment == nil {
+			continue
+		}
+		data, err := io.ReadAll(e.attachment.Data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read attachment %s", e.attachment.Name)
+		}
+		snapshots[i] = data
+	}
+	return snapshots, nil
+}
+
+// resetAttachments points each entry's attachment at a fresh reader over its
+// snapshot, so a retry resends the full attachment instead of an
+// empty/truncated one left behind by the previous attempt.
+func resetAttachments(batch []*logEntry, snapshots [][]byte) {
+	for i, e := range batch {
+		if e.attachment == nil {
+			continue
+		}
+		e.attachment.Data = bytes.NewReader(snapshots[i])
+	}
+}
+
+// send builds and executes a single batched log request. The returned bool
+// reports whether the error is worth retrying (network error or 5xx).
+func (r *LogReporter) send(batch []*logEntry) (bool, error) {
+	req, err := buildBatchLogRequest(r.client, batch)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := r.client.doRequest(context.Background(), req)
+	if err != nil {
+		return true, errors.Wrapf(err, "failed to execute batched POST request %s", req.URL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return true, errors.Errorf("batched log request failed with status %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return false, errors.Errorf("batched log request failed with status %s", resp.Status)
+	}
+	return false, nil
+}
+
+// buildBatchLogRequest packs batch into a single multipart request using
+// ReportPortal's array-form json_request_part, one file part per entry
+// carrying an attachment. The body is streamed through an io.Pipe by a
+// background goroutine rather than assembled in a buffer first; the
+// attachment data itself is already held in memory by sendWithRetry's
+// snapshot of each attachment.
+func buildBatchLogRequest(client *Client, batch []*logEntry) (*http.Request, error) {
+	url := fmt.Sprintf("%s/%s/log", client.Endpoint, client.Project)
+
+	pr, pw := io.Pipe()
+	bodyWriter := multipart.NewWriter(pw)
+
+	go func() {
+		if err := writeBatchLogMultipart(bodyWriter, batch); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.Close()
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, url, pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+	req.Header.Set("Content-Type", bodyWriter.FormDataContentType())
+	return req, nil
+}
+
+// writeBatchLogMultipart writes the json_request_part (one entry per batch
+// item) followed by each entry's attachment file part into bodyWriter, then closes it.
+func writeBatchLogMultipart(bodyWriter *multipart.Writer, batch []*logEntry) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="json_request_part"`)
+	h.Set("Content-Type", "application/json")
+	reqWriter, err := bodyWriter.CreatePart(h)
+	if err != nil {
+		return errors.Wrap(err, "failed to create form file")
+	}
+
+	parts := make(jsonRequestPart, len(batch))
+	for i, e := range batch {
+		var f *fileInfo
+		if e.attachment != nil {
+			f = &fileInfo{Name: e.attachment.Name}
+		}
+		parts[i].File = f
+		parts[i].ItemId = e.item.Id
+		parts[i].Level = e.level
+		parts[i].Message = e.message
+		parts[i].Time = toTimestamp(time.Now())
+	}
+
+	bs, err := json.Marshal(&parts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal to JSON: %v", parts)
+	}
+	if _, err := io.Copy(reqWriter, bytes.NewReader(bs)); err != nil {
+		return errors.Wrap(err, "failed to copy reader")
+	}
+
+	for _, e := range batch {
+		if e.attachment == nil {
+			continue
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, "file", e.attachment.Name))
+		h.Set("Content-Type", e.attachment.MimeType)
+
+		fileWriter, err := bodyWriter.CreatePart(h)
+		if err != nil {
+			return errors.Wrap(err, "failed to create form file")
+		}
+		if _, err := io.Copy(fileWriter, e.attachment.Data); err != nil {
+			return errors.Wrap(err, "failed to copy file writer")
+		}
+	}
+	return bodyWriter.Close()
+}
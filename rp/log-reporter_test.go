@@ -0,0 +1,154 @@
+package rp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestLogReporterPushDuringClose pushes log entries from a background
+// goroutine while Close is called concurrently, which used to panic on a
+// send to a closed channel.
+func TestLogReporterPushDuringClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "project", "token", 1)
+	r := NewLogReporter(client, LogReporterConfig{
+		Workers:      2,
+		BatchSize:    5,
+		BatchTimeout: 5 * time.Millisecond,
+		QueueSize:    100,
+	})
+	item := &TestItem{Id: "item-1", client: client}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				r.Push(item, "msg", LevelInfo, nil)
+			}
+		}
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// TestLogReporterRetriesOn5xx sends a batch that fails once with a 5xx, then
+// confirms sendWithRetry retries it instead of dropping it.
+func TestLogReporterRetriesOn5xx(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "project", "token", 1)
+	r := NewLogReporter(client, LogReporterConfig{
+		Workers:        1,
+		BatchSize:      1,
+		BatchTimeout:   5 * time.Millisecond,
+		QueueSize:      10,
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	})
+	defer r.Close()
+
+	item := &TestItem{Id: "item-1", client: client}
+	r.Push(item, "boom", LevelError, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected 2 requests (initial failure + retry), got %d", got)
+	}
+}
+
+// TestLogReporterRetriesFullAttachmentAfter5xx sends a batch with an
+// attachment backed by a one-shot io.Reader, has the server 500 the first
+// attempt, and confirms the retry resends the attachment in full instead of
+// an empty/truncated one left behind by the drained reader.
+func TestLogReporterRetriesFullAttachmentAfter5xx(t *testing.T) {
+	const content = "attachment-body"
+	var calls int32
+	var gotBodies []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("failed to parse multipart form: %v", err)
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("failed to read file part: %v", err)
+		}
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatalf("failed to read file contents: %v", err)
+		}
+		gotBodies = append(gotBodies, string(b))
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "project", "token", 1)
+	r := NewLogReporter(client, LogReporterConfig{
+		Workers:        1,
+		BatchSize:      1,
+		BatchTimeout:   5 * time.Millisecond,
+		QueueSize:      10,
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	})
+	defer r.Close()
+
+	item := &TestItem{Id: "item-1", client: client}
+	attachment := &Attachment{Name: "log.txt", MimeType: "text/plain", Data: strings.NewReader(content)}
+	r.Push(item, "boom", LevelError, attachment)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected 2 requests (initial failure + retry), got %d", got)
+	}
+	if len(gotBodies) != 2 || gotBodies[0] != content || gotBodies[1] != content {
+		t.Fatalf("expected both attempts to carry the full attachment %q, got %v", content, gotBodies)
+	}
+}
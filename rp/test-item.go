@@ -2,12 +2,14 @@ package rp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -86,6 +88,29 @@ func NewTestItem(launch *Launch, name, description, itemType string, tags []stri
 
 // Start starts specified test item
 func (ti *TestItem) Start() error {
+	return ti.StartCtx(context.Background())
+}
+
+// StartCtx starts specified test item, honoring ctx's deadline and cancellation
+func (ti *TestItem) StartCtx(ctx context.Context) error {
+	return ti.startCtx(ctx, "")
+}
+
+// StartRetry starts ti as a retry of the previously reported attempt
+// identified by previousItemID, so ReportPortal links the new attempt to the
+// prior one and collapses both into a single row in history views
+func (ti *TestItem) StartRetry(previousItemID string) error {
+	return ti.StartRetryCtx(context.Background(), previousItemID)
+}
+
+// StartRetryCtx starts ti as a retry of previousItemID, honoring ctx's deadline and cancellation
+func (ti *TestItem) StartRetryCtx(ctx context.Context, previousItemID string) error {
+	ti.Retry = true
+	return ti.startCtx(ctx, previousItemID)
+}
+
+// startCtx starts ti, optionally as a retry of retryOf (the empty string for a fresh item)
+func (ti *TestItem) startCtx(ctx context.Context, retryOf string) error {
 	var url string
 	if ti.Parent != nil {
 		url = fmt.Sprintf("%s/%s/item/%s", ti.client.Endpoint, ti.client.Project, ti.Parent.Id)
@@ -99,6 +124,8 @@ func (ti *TestItem) Start() error {
 		StartTime   int64    `json:"start_time"`
 		LaunchId    string   `json:"launch_id"`
 		Type        string   `json:"type"`
+		Retry       bool     `json:"retry,omitempty"`
+		RetryOf     string   `json:"retry_of,omitempty"`
 		Parameters  []struct {
 			Key   string `json:"key"`
 			Value string `json:"value"`
@@ -110,6 +137,8 @@ func (ti *TestItem) Start() error {
 		StartTime:   toTimestamp(time.Now()),
 		LaunchId:    ti.launch.Id,
 		Type:        ti.Type,
+		Retry:       ti.Retry,
+		RetryOf:     retryOf,
 	}
 
 	b, err := json.Marshal(&data)
@@ -118,18 +147,19 @@ func (ti *TestItem) Start() error {
 	}
 
 	r := bytes.NewReader(b)
-	req, err := http.NewRequest(http.MethodPost, url, r)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, r)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create POST request to %s", url)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := doRequest(req, ti.client.Token)
-	defer resp.Body.Close()
+	resp, err := ti.client.doRequest(ctx, req)
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute POST request %s", req.URL)
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusCreated {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
@@ -146,6 +176,21 @@ func (ti *TestItem) Start() error {
 
 // Finish finishes specified test item
 func (ti *TestItem) Finish(status string) error {
+	return ti.FinishCtx(context.Background(), status)
+}
+
+// FinishCtx finishes specified test item, honoring ctx's deadline and
+// cancellation. If ti has outstanding nested steps started via
+// StartNestedStep, they are finished first, innermost first, with the same
+// status, since ReportPortal requires a parent's children to be finished
+// before the parent itself.
+func (ti *TestItem) FinishCtx(ctx context.Context, status string) error {
+	if ti.launch != nil {
+		if err := ti.launch.finishDescendants(ctx, ti, status); err != nil {
+			return err
+		}
+	}
+
 	url := fmt.Sprintf("%s/%s/item/%s", ti.client.Endpoint, ti.client.Project, ti.Id)
 	data := struct {
 		EndTime int64  `json:"end_time"`
@@ -158,42 +203,97 @@ func (ti *TestItem) Finish(status string) error {
 	}
 
 	r := bytes.NewReader(b)
-	req, err := http.NewRequest(http.MethodPut, url, r)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, r)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create PUT request to %s", url)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := doRequest(req, ti.client.Token)
-	defer resp.Body.Close()
+	resp, err := ti.client.doRequest(ctx, req)
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute PUT request to %s", req.URL)
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
+
+	if ti.launch != nil {
+		ti.launch.removeItem(ti)
+	}
 	return nil
 }
 
+// StartNestedStep starts a child STEP item under ti and pushes it onto
+// ti.launch's open-item stack, so BDD-style frameworks reporting arbitrarily
+// nested Given/When/Then steps don't have to track parents themselves. Use
+// ti.launch.CurrentItem or ti.launch.PopItem to retrieve the step without
+// holding onto the returned *TestItem directly.
+func (ti *TestItem) StartNestedStep(name, description string) (*TestItem, error) {
+	return ti.StartNestedStepCtx(context.Background(), name, description)
+}
+
+// StartNestedStepCtx is StartNestedStep with a context, honoring ctx's deadline and cancellation
+func (ti *TestItem) StartNestedStepCtx(ctx context.Context, name, description string) (*TestItem, error) {
+	child := NewTestItem(ti.launch, name, description, TestItemStep, nil, ti)
+	if err := child.StartCtx(ctx); err != nil {
+		return nil, err
+	}
+	ti.launch.pushItem(child)
+	return child, nil
+}
+
 // Log sends log for specified test item
 func (ti *TestItem) Log(message, level string, attachment *Attachment) error {
-	var req *http.Request
-	var err error
+	return ti.LogCtx(context.Background(), message, level, attachment)
+}
+
+// LogCtx sends log for specified test item, honoring ctx's deadline and cancellation
+func (ti *TestItem) LogCtx(ctx context.Context, message, level string, attachment *Attachment) error {
 	if attachment != nil {
-		req, err = ti.getReqForLogWithAttach(message, level, attachment)
-	} else {
-		req, err = ti.getReqForLog(message, level)
+		return ti.LogWithAttachmentsCtx(ctx, message, level, []*Attachment{attachment}, nil)
 	}
+
+	req, err := ti.getReqForLog(ctx, message, level)
 	if err != nil {
 		return err
 	}
 
-	resp, err := doRequest(req, ti.client.Token)
+	resp, err := ti.client.doRequest(ctx, req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute POST request %s", req.URL)
+	}
 	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// LogWithAttachments sends a single log entry carrying multiple attachments,
+// packed into one multipart request with a matching multi-entry
+// json_request_part. progress, if non-nil, is called as attachment data is
+// streamed to the request body.
+func (ti *TestItem) LogWithAttachments(message, level string, attachments []*Attachment, progress func(bytesSent, bytesTotal int64)) error {
+	return ti.LogWithAttachmentsCtx(context.Background(), message, level, attachments, progress)
+}
+
+// LogWithAttachmentsCtx is LogWithAttachments with a context, honoring ctx's deadline and cancellation
+func (ti *TestItem) LogWithAttachmentsCtx(ctx context.Context, message, level string, attachments []*Attachment, progress func(bytesSent, bytesTotal int64)) error {
+	req, err := ti.getReqForLogWithAttachments(ctx, message, level, attachments, progress)
+	if err != nil {
+		return err
+	}
+
+	resp, err := ti.client.doRequest(ctx, req)
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute POST request %s", req.URL)
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusCreated {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
@@ -202,6 +302,11 @@ func (ti *TestItem) Log(message, level string, attachment *Attachment) error {
 
 // Update updates launch
 func (ti *TestItem) Update(description string, tags []string) error {
+	return ti.UpdateCtx(context.Background(), description, tags)
+}
+
+// UpdateCtx updates the test item's description and tags, honoring ctx's deadline and cancellation
+func (ti *TestItem) UpdateCtx(ctx context.Context, description string, tags []string) error {
 	url := fmt.Sprintf("%s/%s/item/%s/update", ti.client.Endpoint, ti.client.Project, ti.Id)
 	data := struct {
 		Description string   `json:"description"`
@@ -214,18 +319,19 @@ func (ti *TestItem) Update(description string, tags []string) error {
 	}
 
 	r := bytes.NewReader(b)
-	req, err := http.NewRequest(http.MethodPut, url, r)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, r)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create PUT request to %s", url)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := doRequest(req, ti.client.Token)
-	defer resp.Body.Close()
+	resp, err := ti.client.doRequest(ctx, req)
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute PUT request to %s", req.URL)
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
 		return errors.Errorf("failed with status %s", resp.Status)
 	}
@@ -240,64 +346,133 @@ func (ti *TestItem) GetActivity() (*Activity, error) {
 	return nil, nil
 }
 
-// getReqForLogWithAttach creates request to perform log request with message and attachment
-func (ti *TestItem) getReqForLogWithAttach(message, level string, attachment *Attachment) (*http.Request, error) {
+// getReqForLogWithAttachments creates a request to perform a log request
+// carrying one or more attachments. The multipart body is streamed through an
+// io.Pipe by a background goroutine rather than buffered fully in memory, so
+// multi-megabyte attachments (screenshots, HAR files, video) don't blow up
+// process memory. progress, if non-nil, is called as attachment bytes are
+// copied into the request body.
+func (ti *TestItem) getReqForLogWithAttachments(ctx context.Context, message, level string, attachments []*Attachment, progress func(bytesSent, bytesTotal int64)) (*http.Request, error) {
 	url := fmt.Sprintf("%s/%s/log", ti.client.Endpoint, ti.client.Project)
-	bodyBuf := &bytes.Buffer{}
-	bodyWriter := multipart.NewWriter(bodyBuf)
 
-	// json request part
+	total := int64(0)
+	for _, a := range attachments {
+		sz := attachmentSize(a)
+		if sz < 0 {
+			total = -1
+			break
+		}
+		total += sz
+	}
+
+	pr, pw := io.Pipe()
+	bodyWriter := multipart.NewWriter(pw)
+
+	go func() {
+		if err := writeLogMultipart(bodyWriter, ti.Id, message, level, attachments, total, progress); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.Close()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+	}
+
+	req.Header.Set("Content-Type", bodyWriter.FormDataContentType())
+	return req, nil
+}
+
+// writeLogMultipart writes the json_request_part (one entry per attachment)
+// followed by each attachment's file part into bodyWriter, then closes it.
+func writeLogMultipart(bodyWriter *multipart.Writer, itemId, message, level string, attachments []*Attachment, total int64, progress func(bytesSent, bytesTotal int64)) error {
 	h := make(textproto.MIMEHeader)
 	h.Set("Content-Disposition", `form-data; name="json_request_part"`)
 	h.Set("Content-Type", "application/json")
 	reqWriter, err := bodyWriter.CreatePart(h)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create form file")
+		return errors.Wrap(err, "failed to create form file")
 	}
 
-	f := &fileInfo{attachment.Name}
-	jsonReqPart := &jsonRequestPart{
-		{f, ti.Id, level, message, toTimestamp(time.Now())},
+	parts := make(jsonRequestPart, len(attachments))
+	for i, a := range attachments {
+		parts[i].File = &fileInfo{Name: a.Name}
+		parts[i].ItemId = itemId
+		parts[i].Level = level
+		parts[i].Message = message
+		parts[i].Time = toTimestamp(time.Now())
 	}
-	bs, err := json.Marshal(&jsonReqPart)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to marshal to JSON: %v", jsonReqPart)
-	}
-	reqReader := bytes.NewReader(bs)
 
-	_, err = io.Copy(reqWriter, reqReader)
+	bs, err := json.Marshal(&parts)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to copy reader")
+		return errors.Wrapf(err, "failed to marshal to JSON: %v", parts)
+	}
+	if _, err := io.Copy(reqWriter, bytes.NewReader(bs)); err != nil {
+		return errors.Wrap(err, "failed to copy reader")
 	}
 
-	// file
-	h = make(textproto.MIMEHeader)
-	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, "file", attachment.Name))
-	h.Set("Content-Type", attachment.MimeType)
-
-	fileWriter, err := bodyWriter.CreatePart(h)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create form file")
+	var sent int64
+	for _, a := range attachments {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, "file", a.Name))
+		h.Set("Content-Type", a.MimeType)
+
+		fileWriter, err := bodyWriter.CreatePart(h)
+		if err != nil {
+			return errors.Wrap(err, "failed to create form file")
+		}
+
+		data := a.Data
+		if progress != nil {
+			data = &progressReader{r: a.Data, sent: &sent, total: total, progress: progress}
+		}
+		n, err := io.Copy(fileWriter, data)
+		if err != nil {
+			return errors.Wrap(err, "failed to copy file writer")
+		}
+		if progress == nil {
+			sent += n
+		}
 	}
+	return bodyWriter.Close()
+}
 
-	_, err = io.Copy(fileWriter, attachment.Data)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to copy file writer")
+// attachmentSize returns a's size in bytes if it is known upfront, or -1 if
+// Data's length can't be determined without reading it.
+func attachmentSize(a *Attachment) int64 {
+	switch v := a.Data.(type) {
+	case *bytes.Reader:
+		return int64(v.Len())
+	case *strings.Reader:
+		return int64(v.Len())
+	case interface{ Size() int64 }:
+		return v.Size()
+	default:
+		return -1
 	}
+}
 
-	bodyWriter.Close()
+// progressReader wraps a reader to report cumulative bytes read through progress
+type progressReader struct {
+	r        io.Reader
+	sent     *int64
+	total    int64
+	progress func(bytesSent, bytesTotal int64)
+}
 
-	req, err := http.NewRequest(http.MethodPost, url, bodyBuf)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		*p.sent += int64(n)
+		p.progress(*p.sent, p.total)
 	}
-
-	req.Header.Set("Content-Type", bodyWriter.FormDataContentType())
-	return req, nil
+	return n, err
 }
 
 // getReqForLog creates request to perform log request with message
-func (ti *TestItem) getReqForLog(message, level string) (*http.Request, error) {
+func (ti *TestItem) getReqForLog(ctx context.Context, message, level string) (*http.Request, error) {
 	url := fmt.Sprintf("%s/%s/log", ti.client.Endpoint, ti.client.Project)
 	data := struct {
 		ItemId  string `json:"item_id"`
@@ -312,7 +487,7 @@ func (ti *TestItem) getReqForLog(message, level string) (*http.Request, error) {
 	}
 
 	r := bytes.NewReader(b)
-	req, err := http.NewRequest(http.MethodPost, url, r)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, r)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create POST request to %s", url)
 	}
@@ -0,0 +1,185 @@
+package rp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultHTTPTimeout is used for Client.HTTPClient when none is configured
+const defaultHTTPTimeout = 30 * time.Second
+
+// toTimestamp converts t to the millisecond unix timestamp ReportPortal expects
+func toTimestamp(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// Page specifies pagination for list requests, sent as the page.page,
+// page.size, and page.sort query parameters. A nil *Page leaves ReportPortal's
+// defaults in place.
+type Page struct {
+	Number int
+	Size   int
+	// Sort is a field name, optionally suffixed with ",DESC" or ",ASC"
+	Sort string
+}
+
+// query encodes p's fields as page.page, page.size, and page.sort query
+// parameters, set on q. Zero fields are left unset so ReportPortal's
+// defaults apply.
+func (p *Page) query(q url.Values) {
+	if p == nil {
+		return
+	}
+	if p.Number > 0 {
+		q.Set("page.page", strconv.Itoa(p.Number))
+	}
+	if p.Size > 0 {
+		q.Set("page.size", strconv.Itoa(p.Size))
+	}
+	if p.Sort != "" {
+		q.Set("page.sort", p.Sort)
+	}
+}
+
+// doJSON builds a request for method and url with body JSON-encoded as its
+// payload (skipped if body is nil), executes it, checks the response status
+// against wantStatus, and JSON-decodes the response into out (skipped if out
+// is nil).
+func (c *Client) doJSON(ctx context.Context, method, rawURL string, body interface{}, wantStatus int, out interface{}) error {
+	return c.doJSONPaged(ctx, method, rawURL, body, nil, wantStatus, out)
+}
+
+// doJSONPaged is doJSON with page's fields appended as page.page, page.size,
+// and page.sort query parameters.
+func (c *Client) doJSONPaged(ctx context.Context, method, rawURL string, body interface{}, page *Page, wantStatus int, out interface{}) error {
+	if page != nil {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return errors.Wrapf(err, "can't parse URL %s", rawURL)
+		}
+		q := u.Query()
+		page.query(q)
+		u.RawQuery = q.Encode()
+		rawURL = u.String()
+	}
+
+	var req *http.Request
+	var err error
+	if body != nil {
+		b, merr := json.Marshal(body)
+		if merr != nil {
+			return errors.Wrapf(merr, "failed to marshal request data %v", body)
+		}
+		req, err = http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(b))
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, rawURL, nil)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s request to %s", method, rawURL)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.doRequest(ctx, req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute %s request %s", method, req.URL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		return errors.Errorf("failed with status %s", resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.Wrapf(err, "failed to decode response from %s", req.URL)
+	}
+	return nil
+}
+
+// refresher is implemented by Authenticators that can invalidate a cached
+// credential, so doRequest can force a fresh one after a 401 response.
+type refresher interface {
+	refresh()
+}
+
+// doRequest executes req against ReportPortal using c.HTTPClient, authenticating
+// it with c.Auth and honoring ctx for cancellation and deadlines. If the
+// request comes back 401 and c.Auth can be refreshed and the request body can
+// be resent, doRequest refreshes the credential and retries once.
+func (c *Client) doRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	c.authOnce.Do(func() {
+		if c.Auth == nil {
+			c.Auth = NewStaticTokenAuth(c.Token)
+		}
+	})
+
+	resp, err := c.authenticatedDo(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	r, ok := c.Auth.(refresher)
+	if !ok {
+		return resp, nil
+	}
+	// A request with no body (GET/DELETE) is always safely resendable; only a
+	// request that has a body but can't rebuild it (GetBody nil) can't be retried.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		req.Body = body
+	}
+	r.refresh()
+	return c.authenticatedDo(req)
+}
+
+// authenticatedDo applies c.Auth to req and executes it
+func (c *Client) authenticatedDo(req *http.Request) (*http.Response, error) {
+	if err := c.Auth.Apply(req); err != nil {
+		return nil, errors.Wrap(err, "failed to apply authentication")
+	}
+	return c.httpClient().Do(req)
+}
+
+// httpClient returns c.HTTPClient, lazily defaulting it (once) and wrapping
+// its Transport with c.Middleware if any is configured
+func (c *Client) httpClient() *http.Client {
+	c.httpClientOnce.Do(func() {
+		if c.HTTPClient == nil {
+			c.HTTPClient = &http.Client{Timeout: defaultHTTPTimeout}
+		}
+	})
+	if len(c.Middleware) == 0 {
+		return c.HTTPClient
+	}
+
+	rt := c.HTTPClient.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		rt = c.Middleware[i](rt)
+	}
+	client := *c.HTTPClient
+	client.Transport = rt
+	return &client
+}
@@ -0,0 +1,91 @@
+package rp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// flakyAuth is a test Authenticator that tracks whether refresh was called,
+// so doRequest's 401-refresh-and-resend path can be exercised.
+type flakyAuth struct {
+	mu        sync.Mutex
+	refreshed bool
+}
+
+func (a *flakyAuth) Apply(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer token")
+	return nil
+}
+
+func (a *flakyAuth) refresh() {
+	a.mu.Lock()
+	a.refreshed = true
+	a.mu.Unlock()
+}
+
+// TestDoRequestRefreshesAuthOn401 posts a request whose body can be resent,
+// has the server reject the first attempt with 401, and confirms doRequest
+// refreshes the auth and retries once rather than returning the 401.
+func TestDoRequestRefreshesAuthOn401(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"launch-1"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "project", "token", 1)
+	auth := &flakyAuth{}
+	client.Auth = auth
+
+	launch := NewLaunch(client, "launch", "desc", nil)
+	if err := launch.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !auth.refreshed {
+		t.Fatal("expected auth to be refreshed after a 401")
+	}
+	if launch.Id != "launch-1" {
+		t.Fatalf("expected launch id %q, got %q", "launch-1", launch.Id)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected 2 requests (initial 401 + retry), got %d", got)
+	}
+}
+
+// TestDoRequestRefreshesAuthOn401NilBody issues a GET request (no body to
+// resend) against a server that 401s the first attempt, and confirms
+// doRequest still refreshes and retries instead of treating the absent
+// GetBody on a bodyless request as unretriable.
+func TestDoRequestRefreshesAuthOn401NilBody(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "project", "token", 1)
+	auth := &flakyAuth{}
+	client.Auth = auth
+
+	if err := client.CheckConnect(); err != nil {
+		t.Fatalf("CheckConnect failed: %v", err)
+	}
+	if !auth.refreshed {
+		t.Fatal("expected auth to be refreshed after a 401 on a bodyless request")
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected 2 requests (initial 401 + retry), got %d", got)
+	}
+}